@@ -0,0 +1,66 @@
+package extractor
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/op/go-logging"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("writeFileAtomically", func() {
+	var (
+		fs        *afero.Afero
+		extractor *Extractor
+	)
+
+	BeforeEach(func() {
+		fs = &afero.Afero{Fs: afero.NewMemMapFs()}
+		extractor = &Extractor{Log: logging.MustGetLogger("atomic_write_test"), FileSystem: fs}
+		Expect(fs.MkdirAll("/destination", 0755)).To(Succeed())
+	})
+
+	It("commits its write while other writers' temp files for the same destination are still in flight", func() {
+		destination := "/destination/manifest.yml"
+
+		firstTemp, err := fs.TempFile("/destination", ".manifest.yml.tmp-")
+		Expect(err).ToNot(HaveOccurred())
+		defer fs.Remove(firstTemp.Name())
+
+		secondTemp, err := fs.TempFile("/destination", ".manifest.yml.tmp-")
+		Expect(err).ToNot(HaveOccurred())
+		defer fs.Remove(secondTemp.Name())
+
+		Expect(firstTemp.Name()).ToNot(Equal(secondTemp.Name()))
+
+		written, err := extractor.writeFileAtomically(destination, 0600, strings.NewReader("winner"), nil)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(written).To(Equal(int64(len("winner"))))
+		contents, err := fs.ReadFile(destination)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(contents)).To(Equal("winner"))
+	})
+
+	It("removes the temp file and leaves destination untouched when validate rejects the write", func() {
+		destination := "/destination/manifest.yml"
+		Expect(fs.WriteFile(destination, []byte("original"), 0600)).To(Succeed())
+
+		rejectAll := func(written int64) error {
+			return ErrUnsafeArchive
+		}
+
+		_, err := extractor.writeFileAtomically(destination, 0600, strings.NewReader("attacker-controlled"), rejectAll)
+
+		Expect(err).To(Equal(ErrUnsafeArchive))
+		contents, readErr := fs.ReadFile(destination)
+		Expect(readErr).ToNot(HaveOccurred())
+		Expect(string(contents)).To(Equal("original"), "a rejected write must never overwrite the previous, valid destination")
+
+		entries, err := fs.ReadDir("/destination")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1), "the rejected temp file must be cleaned up, not left behind")
+	})
+})
@@ -1,13 +1,16 @@
-// Package extractor unzips artifacts.
+// Package extractor extracts artifacts of various archive formats.
 package extractor
 
 import (
-	"archive/zip"
-	"fmt"
+	"bytes"
 	"io"
+	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
+	I "github.com/compozed/deployadactyl/interfaces"
 	"github.com/go-errors/errors"
 	"github.com/op/go-logging"
 	"github.com/spf13/afero"
@@ -21,32 +24,84 @@ const (
 	cannotMakeDirectory           = "cannot make directory"
 	cannotOpenFileForWriting      = "cannot open file for writing"
 	cannotWriteToFile             = "cannot write to file"
-	cannotOpenManifestFile        = "cannot open manifest file"
-	cannotPrintToManifestFile     = "cannot print to open manifest file"
+	cannotWriteManifestFile       = "cannot write manifest file"
+	cannotCreateTempFile          = "cannot create temp file for remote artifact"
+	cannotFetchArtifact           = "cannot fetch remote artifact"
+	noFetcherConfigured           = "source is a remote url but no fetcher is configured"
+	cannotSniffArchiveFormat      = "cannot sniff archive format"
+	unrecognizedArchiveFormat     = "unrecognized archive format"
 	niceFixYourZipMessage         = `Please double check your zip compression method and that the correct files are zipped.
 You can try confirming that it's valid on your computer by opening or performing some other action on it. Once you've confirmed that it's valid, please try again.`
 )
 
-// Extractor has a file system from which files are extracted from.
+// Handler extracts an archive of a particular format from file into
+// destination. fileSize is the archive's total size on disk, which zip
+// needs for random access.
+type Handler func(e *Extractor, file afero.File, fileSize int64, destination string) error
+
+// Handlers maps a format name ("zip", "tar", "tar.gz", "tar.bz2") to the
+// Handler responsible for it. Extract looks up the format detected by
+// detectFormat here. Downstream users can register support for additional
+// formats, for example "zstd", without patching this package.
+var Handlers = map[string]Handler{
+	"zip":     extractZip,
+	"tar":     extractTar,
+	"tar.gz":  extractTarGz,
+	"tar.bz2": extractTarBz2,
+}
+
+// ErrUnsafeArchive is returned when an archive entry fails zip-slip,
+// symlink, or decompression-bomb validation, so callers can fail the
+// deploy cleanly instead of extracting unsafe content into the destination.
+var ErrUnsafeArchive = errors.New("unsafe archive")
+
+// Extractor has a file system from which files are extracted from. Fetcher
+// is optional; when set it lets source be a remote artifact URL
+// (http, https, ftp, sftp) instead of a local file path.
+//
+// AllowSymlinks, MaxDecompressedBytes, MaxFiles, and MaxCompressionRatio
+// guard against zip-slip and zip-bomb archives; each is disabled (zero
+// value) by default except AllowSymlinks, which defaults to rejecting
+// symlink entries.
 type Extractor struct {
 	Log        *logging.Logger
 	FileSystem *afero.Afero
+	Fetcher    I.ArtifactFetcher
+
+	AllowSymlinks        bool
+	MaxDecompressedBytes int64
+	MaxFiles             int
+	MaxCompressionRatio  float64
 }
 
-// Unzip unzips from source into destination.
-// If there is no manifest provided to this function, it will attempt to read a manifest file within the zip file.
-func (e *Extractor) Unzip(source, destination, manifest string) error {
+// Extract extracts source into destination. Source is either a local file
+// path or a remote artifact URL; in the latter case it is downloaded to a
+// temp file via Fetcher before extraction, writing a copy of the
+// downloaded bytes to out as they arrive if out is non-nil.
+//
+// The archive format is sniffed from its first bytes, falling back to
+// source's file extension when the magic bytes are ambiguous, and
+// dispatched to the matching Handler in Handlers ("zip", "tar", "tar.gz",
+// or "tar.bz2").
+//
+// If there is no manifest provided to this function, it will attempt to read a manifest file within the archive.
+func (e *Extractor) Extract(source, destination, manifest string, out io.Writer) error {
 	e.Log.Info("extracting application")
 	e.Log.Debug(`parameters for extractor:
 	source: %+v
 	destination: %+v`, source, destination)
 
-	err := e.FileSystem.MkdirAll(destination, 0755)
+	resolvedSource, err := e.resolveSource(source, out)
+	if err != nil {
+		return err
+	}
+
+	err = e.FileSystem.MkdirAll(destination, 0755)
 	if err != nil {
 		return errors.Errorf("%s: %s", cannotCreateDirectory, err)
 	}
 
-	file, err := e.FileSystem.Open(source)
+	file, err := e.FileSystem.Open(resolvedSource)
 	if err != nil {
 		return errors.New(err)
 	}
@@ -57,28 +112,27 @@ func (e *Extractor) Unzip(source, destination, manifest string) error {
 		return errors.New(err)
 	}
 
-	reader, err := zip.NewReader(file, fileStat.Size())
+	// detectFormat's extension fallback must see the original source
+	// (URL or local path), not resolvedSource's temp filename, which
+	// has no extension to fall back on.
+	format, err := detectFormat(file, source)
 	if err != nil {
-		return errors.Errorf("%s: %s: %s\n%s", cannotOpenZipFile, source, err, niceFixYourZipMessage)
+		return err
 	}
 
-	for _, file := range reader.File {
-		err := e.unzipFile(destination, file)
-		if err != nil {
-			return errors.Errorf("%s: %s: %s", cannotExtractFileFromArchive, file.Name, err)
-		}
+	handler, ok := Handlers[format]
+	if !ok {
+		return errors.Errorf("%s: %s", unrecognizedArchiveFormat, format)
 	}
 
-	if manifest != "" {
-		manifestFile, err := e.FileSystem.OpenFile(path.Join(destination, "manifest.yml"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-		if err != nil {
-			return errors.Errorf("%s: %s", cannotOpenManifestFile, err)
-		}
-		defer manifestFile.Close()
+	if err := handler(e, file, fileStat.Size(), destination); err != nil {
+		return err
+	}
 
-		_, err = fmt.Fprint(manifestFile, manifest)
-		if err != nil {
-			return errors.Errorf("%s: %s", cannotPrintToManifestFile, err)
+	if manifest != "" {
+		manifestPath := path.Join(destination, "manifest.yml")
+		if _, err := e.writeFileAtomically(manifestPath, 0600, strings.NewReader(manifest), nil); err != nil {
+			return errors.Errorf("%s: %s", cannotWriteManifestFile, err)
 		}
 	}
 
@@ -86,35 +140,156 @@ func (e *Extractor) Unzip(source, destination, manifest string) error {
 	return nil
 }
 
-func (e *Extractor) unzipFile(destination string, file *zip.File) error {
-	contents, err := file.Open()
+// Unzip is a backwards-compatible alias for Extract, which also supports
+// tar, tar.gz, and tar.bz2 archives despite the name.
+func (e *Extractor) Unzip(source, destination, manifest string) error {
+	return e.Extract(source, destination, manifest, nil)
+}
+
+// detectFormat identifies file's archive format by sniffing its first 512
+// bytes for known magic numbers, falling back to source's file extension
+// when the magic bytes are ambiguous (for example a tar archive with no
+// ustar header). file's read offset is restored before returning.
+func detectFormat(file afero.File, source string) (string, error) {
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", errors.Errorf("%s: %s", cannotSniffArchiveFormat, err)
+	}
+	header = header[:n]
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", errors.Errorf("%s: %s", cannotSniffArchiveFormat, err)
+	}
+
+	switch {
+	case len(header) >= 4 && bytes.Equal(header[:4], []byte{'P', 'K', 0x03, 0x04}):
+		return "zip", nil
+	case len(header) >= 2 && header[0] == 0x1f && header[1] == 0x8b:
+		return "tar.gz", nil
+	case len(header) >= 3 && bytes.Equal(header[:3], []byte("BZh")):
+		return "tar.bz2", nil
+	case len(header) >= 262 && bytes.Equal(header[257:262], []byte("ustar")):
+		return "tar", nil
+	}
+
+	switch {
+	case strings.HasSuffix(source, ".tar.bz2"), strings.HasSuffix(source, ".tbz2"):
+		return "tar.bz2", nil
+	case strings.HasSuffix(source, ".tar.gz"), strings.HasSuffix(source, ".tgz"):
+		return "tar.gz", nil
+	case strings.HasSuffix(source, ".tar"):
+		return "tar", nil
+	case strings.HasSuffix(source, ".zip"):
+		return "zip", nil
+	}
+
+	return "", errors.Errorf("%s: %s", unrecognizedArchiveFormat, source)
+}
+
+// safeJoin resolves name against destination and rejects any entry whose
+// cleaned, absolute path is not a descendant of destination (zip-slip),
+// returning ErrUnsafeArchive.
+func (e *Extractor) safeJoin(destination, name string) (string, error) {
+	cleanedDestination, err := filepath.Abs(filepath.Clean(destination))
 	if err != nil {
-		return errors.Errorf("%s: %s", cannotExtractFileFromArchive, err)
+		return "", errors.Errorf("%s: %s", cannotCreateDirectory, err)
 	}
-	defer contents.Close()
 
-	if file.FileInfo().IsDir() {
-		return nil
+	joined := filepath.Join(cleanedDestination, name)
+	if joined != cleanedDestination && !strings.HasPrefix(joined, cleanedDestination+string(os.PathSeparator)) {
+		return "", ErrUnsafeArchive
 	}
 
-	savedLocation := path.Join(destination, file.Name)
-	directory := path.Dir(savedLocation)
-	err = e.FileSystem.MkdirAll(directory, 0755)
+	return joined, nil
+}
+
+// writeFileAtomically writes reader's contents to destination using the
+// standard temp-file-plus-rename pattern: it writes to a uniquely-named
+// temp file in the same directory, fsyncs and closes it, and only then
+// renames it over destination. The temp name is unique per call (rather
+// than a fixed destination+".tmp") so that two concurrent writers to the
+// same destination, for example two deploys retrying into the same
+// destination directory, never truncate or interleave with each other's
+// in-flight write. If validate is non-nil it is called with the number of
+// bytes written before the temp file is committed; a rejected write (for
+// example one that exceeds Extractor's decompressed-size guard) never
+// reaches destination. If any step fails the temp file is removed, so a
+// process killed mid-write, or an archive entry rejected by validate,
+// never leaves a truncated, partial, or over-limit file at destination for
+// a subsequent Exists-guarded rerun to mistake for valid.
+func (e *Extractor) writeFileAtomically(destination string, mode os.FileMode, reader io.Reader, validate func(written int64) error) (int64, error) {
+	tempFile, err := e.FileSystem.TempFile(path.Dir(destination), "."+path.Base(destination)+".tmp-")
 	if err != nil {
-		return errors.Errorf("%s: %s: %s", cannotMakeDirectory, directory, err)
+		return 0, errors.Errorf("%s: %s", cannotOpenFileForWriting, err)
+	}
+	tempPath := tempFile.Name()
+
+	if err := e.FileSystem.Chmod(tempPath, mode); err != nil {
+		tempFile.Close()
+		e.FileSystem.Remove(tempPath)
+		return 0, errors.Errorf("%s: %s", cannotOpenFileForWriting, err)
 	}
 
-	mode := file.Mode()
-	newFile, err := e.FileSystem.OpenFile(savedLocation, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	written, err := io.Copy(tempFile, reader)
 	if err != nil {
-		return errors.Errorf("%s: %s: %s", cannotOpenFileForWriting, savedLocation, err)
+		tempFile.Close()
+		e.FileSystem.Remove(tempPath)
+		return written, errors.Errorf("%s: %s: %s", cannotWriteToFile, destination, err)
+	}
+
+	if validate != nil {
+		if err := validate(written); err != nil {
+			tempFile.Close()
+			e.FileSystem.Remove(tempPath)
+			return written, err
+		}
+	}
+
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		e.FileSystem.Remove(tempPath)
+		return written, errors.Errorf("%s: %s: %s", cannotWriteToFile, destination, err)
+	}
+
+	if err := tempFile.Close(); err != nil {
+		e.FileSystem.Remove(tempPath)
+		return written, errors.Errorf("%s: %s: %s", cannotWriteToFile, destination, err)
+	}
+
+	if err := e.FileSystem.Rename(tempPath, destination); err != nil {
+		e.FileSystem.Remove(tempPath)
+		return written, errors.Errorf("%s: %s: %s", cannotWriteToFile, destination, err)
+	}
+
+	return written, nil
+}
+
+// resolveSource returns a local file path for source. If source is a
+// remote artifact URL it is downloaded via Fetcher into a temp file on
+// FileSystem and that temp file's path is returned; a plain local path is
+// returned unchanged. If out is non-nil it is passed to Fetcher.Fetch so
+// the download's progress is written to it as the bytes arrive.
+func (e *Extractor) resolveSource(source string, out io.Writer) (string, error) {
+	parsed, err := url.Parse(source)
+	if err != nil || parsed.Scheme == "" {
+		return source, nil
+	}
+
+	if e.Fetcher == nil {
+		return "", errors.Errorf("%s: %s", noFetcherConfigured, source)
 	}
-	defer newFile.Close()
 
-	_, err = io.Copy(newFile, contents)
+	tempFile, err := e.FileSystem.TempFile("", "deployadactyl-artifact-")
 	if err != nil {
-		return errors.Errorf("%s: %s: %s", cannotWriteToFile, savedLocation, err)
+		return "", errors.Errorf("%s: %s", cannotCreateTempFile, err)
 	}
+	defer tempFile.Close()
 
-	return nil
+	e.Log.Debugf("fetching remote artifact %s", source)
+	if _, err := e.Fetcher.Fetch(source, tempFile, out); err != nil {
+		return "", errors.Errorf("%s: %s", cannotFetchArtifact, err)
+	}
+
+	return tempFile.Name(), nil
 }
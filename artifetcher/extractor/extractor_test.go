@@ -0,0 +1,163 @@
+package extractor_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/compozed/deployadactyl/artifetcher/extractor"
+	"github.com/op/go-logging"
+	"github.com/spf13/afero"
+)
+
+func zipArchive(entries map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for name, contents := range entries {
+		entryWriter, err := writer.Create(name)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = entryWriter.Write([]byte(contents))
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(writer.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+func tarArchive(entries map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	writer := tar.NewWriter(buf)
+	for name, contents := range entries {
+		Expect(writer.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(contents)),
+		})).To(Succeed())
+		_, err := writer.Write([]byte(contents))
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(writer.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+var _ = Describe("Extractor", func() {
+	var (
+		fs          *afero.Afero
+		extractor   *Extractor
+		destination string
+	)
+
+	BeforeEach(func() {
+		fs = &afero.Afero{Fs: afero.NewMemMapFs()}
+		extractor = &Extractor{Log: logging.MustGetLogger("extractor_test"), FileSystem: fs}
+		destination = "/destination"
+	})
+
+	writeArchive := func(name string, contents []byte) string {
+		source := "/source/" + name
+		Expect(fs.MkdirAll("/source", 0755)).To(Succeed())
+		Expect(fs.WriteFile(source, contents, 0644)).To(Succeed())
+		return source
+	}
+
+	Describe("zip-slip protection", func() {
+		It("rejects an archive entry that escapes destination and leaves nothing behind", func() {
+			archive := zipArchive(map[string]string{"../escape.txt": "pwned"})
+			source := writeArchive("evil.zip", archive)
+
+			err := extractor.Extract(source, destination, "", nil)
+
+			Expect(err).To(Equal(ErrUnsafeArchive))
+			exists, err := fs.Exists("/escape.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+
+		It("rejects the same escaping entry in a tar archive", func() {
+			archive := tarArchive(map[string]string{"../escape.txt": "pwned"})
+			source := writeArchive("evil.tar", archive)
+
+			err := extractor.Extract(source, destination, "", nil)
+
+			Expect(err).To(Equal(ErrUnsafeArchive))
+			exists, err := fs.Exists("/escape.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+	})
+
+	Describe("decompression bomb protection", func() {
+		It("rejects an archive whose decompressed size exceeds MaxDecompressedBytes before committing it to destination", func() {
+			extractor.MaxDecompressedBytes = 4
+			archive := zipArchive(map[string]string{"big.txt": "way more than four bytes"})
+			source := writeArchive("bomb.zip", archive)
+
+			err := extractor.Extract(source, destination, "", nil)
+
+			Expect(err).To(Equal(ErrUnsafeArchive))
+			exists, err := fs.Exists(destination + "/big.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exists).To(BeFalse(), "oversized entry must not be left behind at its final destination")
+		})
+
+		It("rejects an archive with more entries than MaxFiles", func() {
+			extractor.MaxFiles = 1
+			archive := zipArchive(map[string]string{"a.txt": "a", "b.txt": "b"})
+			source := writeArchive("many.zip", archive)
+
+			err := extractor.Extract(source, destination, "", nil)
+
+			Expect(err).To(Equal(ErrUnsafeArchive))
+		})
+
+		It("extracts a well-behaved archive within the limit", func() {
+			extractor.MaxDecompressedBytes = 1024
+			archive := zipArchive(map[string]string{"hello.txt": "hello world"})
+			source := writeArchive("good.zip", archive)
+
+			err := extractor.Extract(source, destination, "", nil)
+
+			Expect(err).ToNot(HaveOccurred())
+			contents, err := fs.ReadFile(destination + "/hello.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("hello world"))
+		})
+	})
+
+	Describe("symlink protection", func() {
+		It("rejects a zip entry whose mode bits mark it a symlink unless AllowSymlinks is set", func() {
+			buf := &bytes.Buffer{}
+			writer := zip.NewWriter(buf)
+			header := &zip.FileHeader{Name: "link"}
+			header.SetMode(0777 | os.ModeSymlink)
+			entryWriter, err := writer.CreateHeader(header)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = entryWriter.Write([]byte("/etc/passwd"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(writer.Close()).To(Succeed())
+
+			source := writeArchive("symlink.zip", buf.Bytes())
+
+			err = extractor.Extract(source, destination, "", nil)
+
+			Expect(err).To(Equal(ErrUnsafeArchive))
+		})
+	})
+
+	Describe("manifest handling", func() {
+		It("writes the provided manifest into destination", func() {
+			archive := zipArchive(map[string]string{"hello.txt": "hello world"})
+			source := writeArchive("good.zip", archive)
+
+			err := extractor.Extract(source, destination, "applications:\n- name: my-app", nil)
+
+			Expect(err).ToNot(HaveOccurred())
+			contents, err := fs.ReadFile(destination + "/manifest.yml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("applications:\n- name: my-app"))
+		})
+	})
+})
@@ -0,0 +1,62 @@
+package extractor_test
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/compozed/deployadactyl/artifetcher/extractor"
+	"github.com/op/go-logging"
+	"github.com/spf13/afero"
+)
+
+// fakeFetcher copies archive into dest, simulating a remote download that
+// leaves the resolved local path as an extensionless temp file, and writes
+// to progress if it is non-nil.
+type fakeFetcher struct {
+	archive []byte
+}
+
+func (f *fakeFetcher) Fetch(rawURL string, dest afero.File, progress io.Writer) (int64, error) {
+	var reader io.Reader = bytes.NewReader(f.archive)
+	if progress != nil {
+		reader = io.TeeReader(reader, progress)
+	}
+	return io.Copy(dest, reader)
+}
+
+var _ = Describe("remote source format detection", func() {
+	var (
+		fs          *afero.Afero
+		fetcher     *fakeFetcher
+		extractor   *Extractor
+		destination string
+	)
+
+	BeforeEach(func() {
+		fs = &afero.Afero{Fs: afero.NewMemMapFs()}
+		destination = "/destination"
+	})
+
+	It("falls back to the remote source URL's extension for a pre-POSIX tar with no ustar magic", func() {
+		// No ustar magic bytes anywhere in these 600 zero bytes, so the
+		// magic-sniffing switch can't identify it; only the ".tar"
+		// extension on the original source URL can.
+		fetcher = &fakeFetcher{archive: make([]byte, 600)}
+		extractor = &Extractor{Log: logging.MustGetLogger("format_detection_test"), FileSystem: fs, Fetcher: fetcher}
+
+		err := extractor.Extract("http://artifacts.example.com/build/legacy.tar", destination, "", nil)
+
+		// The archive content is garbage, so extraction itself still
+		// fails, but it must fail while trying to parse it as a tar
+		// (it reached the "tar" handler), not with
+		// unrecognizedArchiveFormat (which means the fallback used the
+		// downloaded temp file's extensionless name instead of the
+		// original source URL's ".tar" extension).
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).ToNot(ContainSubstring("unrecognized archive format"))
+	})
+
+})
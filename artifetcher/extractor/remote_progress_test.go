@@ -0,0 +1,45 @@
+package extractor_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/compozed/deployadactyl/artifetcher/extractor"
+	"github.com/op/go-logging"
+	"github.com/spf13/afero"
+)
+
+type countingWriter struct {
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += len(p)
+	return len(p), nil
+}
+
+var _ = Describe("remote artifact progress", func() {
+	It("threads Extract's out writer through to the fetcher as the download progresses", func() {
+		fs := &afero.Afero{Fs: afero.NewMemMapFs()}
+		archive := tarArchive(map[string]string{"hello.txt": "hello world"})
+		fetcher := &fakeFetcher{archive: archive}
+		extractor := &Extractor{Log: logging.MustGetLogger("remote_progress_test"), FileSystem: fs, Fetcher: fetcher}
+		progress := &countingWriter{}
+
+		err := extractor.Extract("http://artifacts.example.com/build/app.tar", "/destination", "", progress)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(progress.n).To(Equal(len(archive)), "out must receive a copy of every byte the fetcher downloads")
+	})
+
+	It("does not require an out writer", func() {
+		fs := &afero.Afero{Fs: afero.NewMemMapFs()}
+		archive := tarArchive(map[string]string{"hello.txt": "hello world"})
+		fetcher := &fakeFetcher{archive: archive}
+		extractor := &Extractor{Log: logging.MustGetLogger("remote_progress_test"), FileSystem: fs, Fetcher: fetcher}
+
+		err := extractor.Extract("http://artifacts.example.com/build/app.tar", "/destination", "", nil)
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,122 @@
+package extractor
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/go-errors/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	cannotOpenGzipFile  = "cannot open gzip file"
+	cannotOpenBzip2File = "cannot open bzip2 file"
+)
+
+// extractTar is the Handler for the "tar" format.
+func extractTar(e *Extractor, file afero.File, fileSize int64, destination string) error {
+	return e.extractTarReader(tar.NewReader(file), destination)
+}
+
+// extractTarGz is the Handler for the "tar.gz" format.
+func extractTarGz(e *Extractor, file afero.File, fileSize int64, destination string) error {
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return errors.Errorf("%s: %s", cannotOpenGzipFile, err)
+	}
+	defer gzipReader.Close()
+
+	return e.extractTarReader(tar.NewReader(gzipReader), destination)
+}
+
+// extractTarBz2 is the Handler for the "tar.bz2" format.
+func extractTarBz2(e *Extractor, file afero.File, fileSize int64, destination string) error {
+	bzip2Reader, err := bzip2.NewReader(file, nil)
+	if err != nil {
+		return errors.Errorf("%s: %s", cannotOpenBzip2File, err)
+	}
+
+	return e.extractTarReader(tar.NewReader(bzip2Reader), destination)
+}
+
+// extractTarReader reads entries from reader into destination, applying
+// the same zip-slip, symlink, and decompressed-size guards as the zip
+// handler. Tar has no per-entry compressed size, so MaxCompressionRatio is
+// not enforced here.
+func (e *Extractor) extractTarReader(reader *tar.Reader, destination string) error {
+	var extractedFiles int
+	var extractedBytes int64
+
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Errorf("%s: %s", cannotExtractFileFromArchive, err)
+		}
+
+		extractedFiles++
+		if e.MaxFiles > 0 && extractedFiles > e.MaxFiles {
+			return ErrUnsafeArchive
+		}
+
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+
+		if header.Typeflag == tar.TypeSymlink && !e.AllowSymlinks {
+			return ErrUnsafeArchive
+		}
+
+		written, err := e.writeTarEntry(reader, header, destination, &extractedBytes)
+		if err != nil {
+			if err == ErrUnsafeArchive {
+				return err
+			}
+			return errors.Errorf("%s: %s: %s", cannotExtractFileFromArchive, header.Name, err)
+		}
+		extractedBytes += written
+	}
+}
+
+func (e *Extractor) writeTarEntry(reader *tar.Reader, header *tar.Header, destination string, extractedBytes *int64) (int64, error) {
+	savedLocation, err := e.safeJoin(destination, header.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	directory := path.Dir(savedLocation)
+	if err := e.FileSystem.MkdirAll(directory, 0755); err != nil {
+		return 0, errors.Errorf("%s: %s: %s", cannotMakeDirectory, directory, err)
+	}
+
+	var entryReader io.Reader = reader
+	limited := false
+	if e.MaxDecompressedBytes > 0 {
+		remaining := e.MaxDecompressedBytes - *extractedBytes
+		if remaining <= 0 {
+			return 0, ErrUnsafeArchive
+		}
+		entryReader = io.LimitReader(reader, remaining+1)
+		limited = true
+	}
+
+	// Preserve the Unix file mode from the tar header the same way the
+	// zip handler preserves file.Mode() from zip entries.
+	written, err := e.writeFileAtomically(savedLocation, os.FileMode(header.Mode), entryReader, func(written int64) error {
+		if limited && *extractedBytes+written > e.MaxDecompressedBytes {
+			return ErrUnsafeArchive
+		}
+		return nil
+	})
+	if err != nil {
+		return written, err
+	}
+
+	return written, nil
+}
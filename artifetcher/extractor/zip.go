@@ -0,0 +1,97 @@
+package extractor
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path"
+
+	"github.com/go-errors/errors"
+	"github.com/spf13/afero"
+)
+
+// extractZip is the Handler for the "zip" format.
+func extractZip(e *Extractor, file afero.File, fileSize int64, destination string) error {
+	reader, err := zip.NewReader(file, fileSize)
+	if err != nil {
+		return errors.Errorf("%s: %s: %s\n%s", cannotOpenZipFile, file.Name(), err, niceFixYourZipMessage)
+	}
+
+	var extractedFiles int
+	var extractedBytes int64
+
+	for _, zipFile := range reader.File {
+		extractedFiles++
+		if e.MaxFiles > 0 && extractedFiles > e.MaxFiles {
+			return ErrUnsafeArchive
+		}
+
+		err := e.unzipFile(destination, zipFile, &extractedBytes)
+		if err != nil {
+			if err == ErrUnsafeArchive {
+				return err
+			}
+			return errors.Errorf("%s: %s: %s", cannotExtractFileFromArchive, zipFile.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *Extractor) unzipFile(destination string, file *zip.File, extractedBytes *int64) error {
+	if file.FileInfo().IsDir() {
+		return nil
+	}
+
+	if file.Mode()&os.ModeSymlink != 0 && !e.AllowSymlinks {
+		return ErrUnsafeArchive
+	}
+
+	savedLocation, err := e.safeJoin(destination, file.Name)
+	if err != nil {
+		return err
+	}
+
+	if e.MaxCompressionRatio > 0 && file.CompressedSize64 > 0 {
+		ratio := float64(file.UncompressedSize64) / float64(file.CompressedSize64)
+		if ratio > e.MaxCompressionRatio {
+			return ErrUnsafeArchive
+		}
+	}
+
+	contents, err := file.Open()
+	if err != nil {
+		return errors.Errorf("%s: %s", cannotExtractFileFromArchive, err)
+	}
+	defer contents.Close()
+
+	directory := path.Dir(savedLocation)
+	err = e.FileSystem.MkdirAll(directory, 0755)
+	if err != nil {
+		return errors.Errorf("%s: %s: %s", cannotMakeDirectory, directory, err)
+	}
+
+	var reader io.Reader = contents
+	limited := false
+	if e.MaxDecompressedBytes > 0 {
+		remaining := e.MaxDecompressedBytes - *extractedBytes
+		if remaining <= 0 {
+			return ErrUnsafeArchive
+		}
+		reader = io.LimitReader(contents, remaining+1)
+		limited = true
+	}
+
+	written, err := e.writeFileAtomically(savedLocation, file.Mode(), reader, func(written int64) error {
+		if limited && *extractedBytes+written > e.MaxDecompressedBytes {
+			return ErrUnsafeArchive
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*extractedBytes += written
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Package fetcher downloads remote build artifacts so that they can be
+// handed off to extractor.Extractor the same way a local file path would
+// be.
+package fetcher
+
+import (
+	"io"
+	"net/url"
+	"sync"
+
+	I "github.com/compozed/deployadactyl/interfaces"
+	"github.com/go-errors/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	cannotParseArtifactURL = "cannot parse artifact url"
+	noFetcherRegistered    = "no fetcher registered for scheme"
+)
+
+// Registry maps a URL scheme, for example "http" or "sftp", to the
+// interfaces.ArtifactFetcher responsible for handling it. New schemes can
+// be registered at runtime with Register without modifying this package.
+type Registry struct {
+	mutex    sync.RWMutex
+	fetchers map[string]I.ArtifactFetcher
+}
+
+// NewRegistry returns a Registry with no fetchers registered.
+func NewRegistry() *Registry {
+	return &Registry{fetchers: make(map[string]I.ArtifactFetcher)}
+}
+
+// Register associates scheme with fetcher. Registering the same scheme
+// twice replaces the previously registered fetcher.
+func (r *Registry) Register(scheme string, fetcher I.ArtifactFetcher) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.fetchers[scheme] = fetcher
+}
+
+// Fetch resolves rawURL's scheme against the registry and delegates to the
+// matching ArtifactFetcher. If progress is non-nil it receives a copy of
+// the downloaded bytes as they arrive.
+func (r *Registry) Fetch(rawURL string, dest afero.File, progress io.Writer) (int64, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, errors.Errorf("%s: %s: %s", cannotParseArtifactURL, rawURL, err)
+	}
+
+	r.mutex.RLock()
+	fetcher, ok := r.fetchers[parsed.Scheme]
+	r.mutex.RUnlock()
+	if !ok {
+		return 0, errors.Errorf("%s: %s", noFetcherRegistered, parsed.Scheme)
+	}
+
+	return fetcher.Fetch(rawURL, dest, progress)
+}
@@ -0,0 +1,102 @@
+package fetcher_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/compozed/deployadactyl/artifetcher/fetcher"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("HTTPFetcher", func() {
+	var (
+		server *httptest.Server
+		fs     *afero.Afero
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("artifact contents"))
+		}))
+		fs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("writes the downloaded bytes to dest", func() {
+		dest, err := fs.TempFile("", "fetch-test-")
+		Expect(err).ToNot(HaveOccurred())
+
+		fetcher := &HTTPFetcher{}
+		written, err := fetcher.Fetch(server.URL, dest, nil)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(written).To(Equal(int64(len("artifact contents"))))
+	})
+
+	It("mirrors the downloaded bytes to progress when it is non-nil", func() {
+		dest, err := fs.TempFile("", "fetch-test-")
+		Expect(err).ToNot(HaveOccurred())
+		progress := &countingWriter{}
+
+		fetcher := &HTTPFetcher{}
+		_, err = fetcher.Fetch(server.URL, dest, progress)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(progress.n).To(Equal(len("artifact contents")))
+	})
+})
+
+var _ = Describe("Registry", func() {
+	It("dispatches Fetch to the fetcher registered for the URL's scheme, passing progress through", func() {
+		fs := &afero.Afero{Fs: afero.NewMemMapFs()}
+		dest, err := fs.TempFile("", "fetch-test-")
+		Expect(err).ToNot(HaveOccurred())
+		progress := &countingWriter{}
+
+		registry := NewRegistry()
+		fake := &fakeFetcher{}
+		registry.Register("fake", fake)
+
+		_, err = registry.Fetch("fake://host/path", dest, progress)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fake.calledWithProgress).To(Equal(progress))
+	})
+
+	It("returns an error when no fetcher is registered for the scheme", func() {
+		fs := &afero.Afero{Fs: afero.NewMemMapFs()}
+		dest, err := fs.TempFile("", "fetch-test-")
+		Expect(err).ToNot(HaveOccurred())
+
+		registry := NewRegistry()
+
+		_, err = registry.Fetch("unknown://host/path", dest, nil)
+
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+type countingWriter struct {
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += len(p)
+	return len(p), nil
+}
+
+type fakeFetcher struct {
+	calledWithProgress io.Writer
+}
+
+func (f *fakeFetcher) Fetch(rawURL string, dest afero.File, progress io.Writer) (int64, error) {
+	f.calledWithProgress = progress
+	return 0, nil
+}
@@ -0,0 +1,88 @@
+package fetcher
+
+import (
+	"io"
+	"net/url"
+	"sync"
+
+	"github.com/go-errors/errors"
+	"github.com/jlaffaye/ftp"
+	"github.com/spf13/afero"
+)
+
+const (
+	cannotParseFTPURL  = "cannot parse ftp url"
+	cannotDialFTPHost  = "cannot dial ftp host"
+	cannotLoginFTPHost = "cannot login to ftp host"
+	cannotRetrieveFile = "cannot retrieve file"
+)
+
+// FTPFetcher downloads artifacts from an FTP server. Connections are
+// pooled per host, since many CF deploys reuse the same jump host. An FTP
+// control connection can only run one command at a time, so mutex guards
+// not just the pool map but every Retr against a pooled connection, for
+// the whole of Fetch; concurrent fetches against the same FTPFetcher are
+// serialized rather than interleaved onto a connection that can't
+// multiplex them.
+type FTPFetcher struct {
+	Username string
+	Password string
+
+	mutex sync.Mutex
+	conns map[string]*ftp.ServerConn
+}
+
+// Fetch downloads rawURL into dest. If progress is non-nil it receives a
+// copy of the bytes as they are downloaded.
+func (f *FTPFetcher) Fetch(rawURL string, dest afero.File, progress io.Writer) (int64, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, errors.Errorf("%s: %s: %s", cannotParseFTPURL, rawURL, err)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	conn, err := f.connection(parsed)
+	if err != nil {
+		return 0, err
+	}
+
+	response, err := conn.Retr(parsed.Path)
+	if err != nil {
+		return 0, errors.Errorf("%s: %s: %s", cannotRetrieveFile, rawURL, err)
+	}
+	defer response.Close()
+
+	var reader io.Reader = response
+	if progress != nil {
+		reader = io.TeeReader(response, progress)
+	}
+
+	return io.Copy(dest, reader)
+}
+
+// connection returns the pooled connection for parsed.Host, dialing and
+// logging in if none is pooled yet. Callers must hold f.mutex.
+func (f *FTPFetcher) connection(parsed *url.URL) (*ftp.ServerConn, error) {
+	if f.conns == nil {
+		f.conns = make(map[string]*ftp.ServerConn)
+	}
+
+	if conn, ok := f.conns[parsed.Host]; ok {
+		return conn, nil
+	}
+
+	conn, err := ftp.Dial(parsed.Host)
+	if err != nil {
+		return nil, errors.Errorf("%s: %s: %s", cannotDialFTPHost, parsed.Host, err)
+	}
+
+	if err := conn.Login(f.Username, f.Password); err != nil {
+		return nil, errors.Errorf("%s: %s: %s", cannotLoginFTPHost, parsed.Host, err)
+	}
+
+	f.conns[parsed.Host] = conn
+
+	return conn, nil
+}
@@ -0,0 +1,197 @@
+package fetcher_test
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/compozed/deployadactyl/artifetcher/fetcher"
+	"github.com/spf13/afero"
+)
+
+// fakeFTPServer is a minimal FTP server implementing just enough of
+// RFC 959 (USER/PASS/TYPE/EPSV/RETR) for jlaffaye/ftp's Dial, Login, and
+// Retr to succeed, so FTPFetcher can be exercised end-to-end without a
+// real FTP server. files maps a path to the bytes RETR should return for
+// it. Each accepted control connection is served independently, so the
+// fake server can also stand in for two concurrent fetches.
+type fakeFTPServer struct {
+	listener net.Listener
+	files    map[string][]byte
+
+	mutex     sync.Mutex
+	retrCalls int
+}
+
+func newFakeFTPServer(files map[string][]byte) (*fakeFTPServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	server := &fakeFTPServer{listener: listener, files: files}
+	go server.acceptLoop()
+	return server, nil
+}
+
+func (s *fakeFTPServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeFTPServer) retrCallCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.retrCalls
+}
+
+func (s *fakeFTPServer) close() {
+	s.listener.Close()
+}
+
+func (s *fakeFTPServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+func (s *fakeFTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	proto := textproto.NewConn(conn)
+	proto.PrintfLine("220 fake FTP server ready")
+
+	var pendingDataConn chan net.Conn
+
+	for {
+		line, err := proto.ReadLine()
+		if err != nil {
+			return
+		}
+
+		parts := strings.SplitN(line, " ", 2)
+		command := parts[0]
+
+		switch command {
+		case "FEAT":
+			proto.PrintfLine("211 no features")
+		case "USER":
+			proto.PrintfLine("331 send password")
+		case "PASS":
+			proto.PrintfLine("230 logged in")
+		case "TYPE":
+			proto.PrintfLine("200 type set")
+		case "EPSV":
+			dataListener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				proto.PrintfLine("451 cannot open data connection")
+				continue
+			}
+			port := dataListener.Addr().(*net.TCPAddr).Port
+			pendingDataConn = make(chan net.Conn, 1)
+			go func() {
+				defer dataListener.Close()
+				dataConn, err := dataListener.Accept()
+				if err != nil {
+					return
+				}
+				pendingDataConn <- dataConn
+			}()
+			proto.PrintfLine("229 Entering Extended Passive Mode (|||%d|)", port)
+		case "RETR":
+			path := ""
+			if len(parts) == 2 {
+				path = parts[1]
+			}
+			proto.PrintfLine("150 opening data connection for %s", path)
+
+			dataConn := <-pendingDataConn
+			dataConn.Write(s.files[path])
+			dataConn.Close()
+
+			s.mutex.Lock()
+			s.retrCalls++
+			s.mutex.Unlock()
+
+			proto.PrintfLine("226 transfer complete")
+		case "QUIT":
+			proto.PrintfLine("221 bye")
+			return
+		default:
+			proto.PrintfLine("500 unrecognized command")
+		}
+	}
+}
+
+var _ = Describe("FTPFetcher", func() {
+	var (
+		server *fakeFTPServer
+		fs     *afero.Afero
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = newFakeFTPServer(map[string][]byte{"/build/app.zip": []byte("artifact contents")})
+		Expect(err).ToNot(HaveOccurred())
+		fs = &afero.Afero{Fs: afero.NewMemMapFs()}
+	})
+
+	AfterEach(func() {
+		server.close()
+	})
+
+	It("downloads the requested file into dest", func() {
+		dest, err := fs.TempFile("", "ftp-fetch-test-")
+		Expect(err).ToNot(HaveOccurred())
+
+		fetcher := &FTPFetcher{}
+		written, err := fetcher.Fetch("ftp://"+server.addr()+"/build/app.zip", dest, nil)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(written).To(Equal(int64(len("artifact contents"))))
+	})
+
+	It("mirrors the downloaded bytes to progress when it is non-nil", func() {
+		dest, err := fs.TempFile("", "ftp-fetch-test-")
+		Expect(err).ToNot(HaveOccurred())
+		progress := &countingWriter{}
+
+		fetcher := &FTPFetcher{}
+		_, err = fetcher.Fetch("ftp://"+server.addr()+"/build/app.zip", dest, progress)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(progress.n).To(Equal(len("artifact contents")))
+	})
+
+	It("serializes two concurrent fetches against the same pooled connection instead of corrupting them", func() {
+		fetcher := &FTPFetcher{}
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		written := make([]int64, 2)
+
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				dest, err := fs.TempFile("", "ftp-fetch-test-")
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				written[i], errs[i] = fetcher.Fetch("ftp://"+server.addr()+"/build/app.zip", dest, nil)
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(errs[0]).ToNot(HaveOccurred())
+		Expect(errs[1]).ToNot(HaveOccurred())
+		Expect(written[0]).To(Equal(int64(len("artifact contents"))))
+		Expect(written[1]).To(Equal(int64(len("artifact contents"))))
+		Expect(server.retrCallCount()).To(Equal(2), "both fetches against the pooled connection must complete, not deadlock or error out from interleaved commands")
+	})
+})
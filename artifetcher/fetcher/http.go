@@ -0,0 +1,69 @@
+package fetcher
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/go-errors/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	cannotBuildRequest  = "cannot build request for artifact"
+	cannotFetchArtifact = "cannot fetch artifact"
+	cannotWriteArtifact = "cannot write artifact to disk"
+	unexpectedStatus    = "unexpected status fetching artifact"
+)
+
+// HTTPFetcher downloads artifacts over http or https, optionally
+// authenticating with HTTP basic auth or a bearer token.
+type HTTPFetcher struct {
+	Username    string
+	Password    string
+	BearerToken string
+	Client      *http.Client
+}
+
+// Fetch downloads rawURL into dest. If progress is non-nil it receives a
+// copy of the bytes as they are downloaded, mirroring what the caller's
+// own out writer (for example pusher.Pusher.Push's) is being written to.
+func (f *HTTPFetcher) Fetch(rawURL string, dest afero.File, progress io.Writer) (int64, error) {
+	request, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return 0, errors.Errorf("%s: %s: %s", cannotBuildRequest, rawURL, err)
+	}
+
+	switch {
+	case f.BearerToken != "":
+		request.Header.Set("Authorization", "Bearer "+f.BearerToken)
+	case f.Username != "":
+		request.SetBasicAuth(f.Username, f.Password)
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, errors.Errorf("%s: %s: %s", cannotFetchArtifact, rawURL, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return 0, errors.Errorf("%s: %s: %s", unexpectedStatus, rawURL, response.Status)
+	}
+
+	var reader io.Reader = response.Body
+	if progress != nil {
+		reader = io.TeeReader(response.Body, progress)
+	}
+
+	written, err := io.Copy(dest, reader)
+	if err != nil {
+		return written, errors.Errorf("%s: %s: %s", cannotWriteArtifact, rawURL, err)
+	}
+
+	return written, nil
+}
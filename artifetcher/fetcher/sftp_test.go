@@ -0,0 +1,185 @@
+package fetcher_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/compozed/deployadactyl/artifetcher/fetcher"
+	"github.com/pkg/sftp"
+	"github.com/spf13/afero"
+	"golang.org/x/crypto/ssh"
+)
+
+func mustGenerateHostKey() ssh.Signer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+	signer, err := ssh.NewSignerFromKey(key)
+	Expect(err).ToNot(HaveOccurred())
+	return signer
+}
+
+// fakeSFTPServer is a minimal SSH+SFTP server backed by pkg/sftp's own
+// Server, so SFTPFetcher can be exercised end-to-end without a real SFTP
+// server. It serves the real OS filesystem, same as pkg/sftp's Server does,
+// so callers fetch whatever real file path they've set up beforehand.
+type fakeSFTPServer struct {
+	listener net.Listener
+	hostKey  ssh.Signer
+	config   *ssh.ServerConfig
+}
+
+func newFakeSFTPServer() (*fakeSFTPServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	hostKey := mustGenerateHostKey()
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	server := &fakeSFTPServer{listener: listener, hostKey: hostKey, config: config}
+	go server.acceptLoop()
+	return server, nil
+}
+
+func (s *fakeSFTPServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeSFTPServer) hostPublicKey() ssh.PublicKey {
+	return s.hostKey.PublicKey()
+}
+
+func (s *fakeSFTPServer) close() {
+	s.listener.Close()
+}
+
+func (s *fakeSFTPServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+func (s *fakeSFTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	sshConn, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for req := range requests {
+				req.Reply(req.Type == "subsystem", nil)
+			}
+		}()
+
+		sftpServer, err := sftp.NewServer(channel)
+		if err != nil {
+			channel.Close()
+			continue
+		}
+		sftpServer.Serve()
+		sftpServer.Close()
+		channel.Close()
+	}
+}
+
+var _ = Describe("SFTPFetcher", func() {
+	var (
+		server     *fakeSFTPServer
+		fs         *afero.Afero
+		remotePath string
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = newFakeSFTPServer()
+		Expect(err).ToNot(HaveOccurred())
+		fs = &afero.Afero{Fs: afero.NewMemMapFs()}
+
+		remote, err := os.CreateTemp("", "sftp-fetch-source-")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = remote.WriteString("artifact contents")
+		Expect(err).ToNot(HaveOccurred())
+		remotePath = remote.Name()
+		remote.Close()
+	})
+
+	AfterEach(func() {
+		server.close()
+		os.Remove(remotePath)
+	})
+
+	It("downloads the requested file into dest when the host key callback accepts the server", func() {
+		dest, err := fs.TempFile("", "sftp-fetch-test-")
+		Expect(err).ToNot(HaveOccurred())
+
+		fetcher := &SFTPFetcher{
+			Password:        "irrelevant",
+			HostKeyCallback: ssh.FixedHostKey(server.hostPublicKey()),
+		}
+		written, err := fetcher.Fetch("sftp://"+server.addr()+remotePath, dest, nil)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(written).To(Equal(int64(len("artifact contents"))))
+	})
+
+	It("mirrors the downloaded bytes to progress when it is non-nil", func() {
+		dest, err := fs.TempFile("", "sftp-fetch-test-")
+		Expect(err).ToNot(HaveOccurred())
+		progress := &countingWriter{}
+
+		fetcher := &SFTPFetcher{
+			Password:        "irrelevant",
+			HostKeyCallback: ssh.FixedHostKey(server.hostPublicKey()),
+		}
+		_, err = fetcher.Fetch("sftp://"+server.addr()+remotePath, dest, progress)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(progress.n).To(Equal(len("artifact contents")))
+	})
+
+	It("rejects the server when the host key callback does not recognize its key", func() {
+		dest, err := fs.TempFile("", "sftp-fetch-test-")
+		Expect(err).ToNot(HaveOccurred())
+
+		impostorKey := mustGenerateHostKey()
+
+		fetcher := &SFTPFetcher{
+			Password:        "irrelevant",
+			HostKeyCallback: ssh.FixedHostKey(impostorKey.PublicKey()),
+		}
+		_, err = fetcher.Fetch("sftp://"+server.addr()+remotePath, dest, nil)
+
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,102 @@
+package pusher
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+
+	S "github.com/compozed/deployadactyl/structs"
+	"github.com/go-errors/errors"
+)
+
+const (
+	cannotBuildHealthCheckRequest = "cannot build health check request"
+	healthCheckRequestFailed      = "health check request failed"
+	healthCheckUnexpectedStatus   = "health check returned unexpected status"
+	healthCheckBodyMismatch       = "health check response body did not match expected pattern"
+)
+
+// HTTPHealthChecker requests an application's health check endpoint over
+// HTTP, retrying with exponential backoff until it reports healthy or the
+// configured number of retries is exhausted.
+type HTTPHealthChecker struct{}
+
+// Check requests url and validates the response against
+// deploymentInfo.HealthCheck's expected statuses and, if configured,
+// expected body pattern.
+func (h HTTPHealthChecker) Check(url string, deploymentInfo S.DeploymentInfo) error {
+	healthCheck := deploymentInfo.HealthCheck
+
+	client := &http.Client{
+		Timeout: healthCheck.Timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: deploymentInfo.SkipSSL},
+		},
+	}
+
+	backoff := healthCheck.BackoffInterval
+	var lastErr error
+
+	for attempt := 0; attempt <= healthCheck.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		lastErr = h.attempt(client, url, healthCheck)
+		if lastErr == nil {
+			return nil
+		}
+	}
+
+	return lastErr
+}
+
+func (h HTTPHealthChecker) attempt(client *http.Client, url string, healthCheck S.HealthCheck) error {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return errors.Errorf("%s: %s: %s", cannotBuildHealthCheckRequest, url, err)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return errors.Errorf("%s: %s: %s", healthCheckRequestFailed, url, err)
+	}
+	defer response.Body.Close()
+
+	if !h.statusExpected(response.StatusCode, healthCheck.ExpectedStatuses) {
+		return errors.Errorf("%s: %s: got %d", healthCheckUnexpectedStatus, url, response.StatusCode)
+	}
+
+	if healthCheck.ExpectedBodyRegex == "" {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return errors.Errorf("%s: %s: %s", healthCheckRequestFailed, url, err)
+	}
+
+	matched, err := regexp.MatchString(healthCheck.ExpectedBodyRegex, string(body))
+	if err != nil || !matched {
+		return errors.Errorf("%s: %s", healthCheckBodyMismatch, url)
+	}
+
+	return nil
+}
+
+func (h HTTPHealthChecker) statusExpected(status int, expected []int) bool {
+	if len(expected) == 0 {
+		return status >= 200 && status < 300
+	}
+
+	for _, s := range expected {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
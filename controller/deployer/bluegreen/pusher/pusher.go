@@ -29,27 +29,44 @@ const (
 	appRouteCreated          = "application route created at %s.%s"
 	outputMessage            = "output from Cloud Foundry:\n"
 	finishedPushSuccessfully = "finished push successfully on %s"
+	checkingHealth           = "checking health of %s at %s"
+	healthCheckFailed        = "health check failed for %s, rolling back: %s"
 )
 
 // Pusher has a courier used to push applications to Cloud Foundry.
+//
+// HealthChecker and HealthCheck are optional. When HealthChecker is set and
+// HealthCheck.Path is set (either on Pusher or as a per-deploy override in
+// deploymentInfo.HealthCheck), Push confirms the new application is healthy
+// before returning, rolling back the deploy if it never becomes healthy.
+// Without a HealthChecker no health check is made.
 type Pusher struct {
-	Courier I.Courier
-	Log     *logging.Logger
+	Courier       I.Courier
+	Log           *logging.Logger
+	HealthChecker I.HealthChecker
+	HealthCheck   S.HealthCheck
 }
 
 // Push pushes a single application to a Clound Foundry instance using blue green deployment.
 // Blue green is done by renaming the current application to appName-venerable.
 // Pushes the new application to the existing appName route with an included load balanced domain if provided.
 //
+// If a HealthChecker and a HealthCheck path are configured, Push confirms the new application is
+// healthy before returning and rolls back the deploy (deleting the new app
+// and restoring the venerable one) if it never becomes healthy, so a
+// canary that never becomes healthy is never promoted.
+//
 // Returns Cloud Foundry logs if there is an error.
 func (p Pusher) Push(appPath, domain string, deploymentInfo S.DeploymentInfo, out io.Writer) ([]byte, error) {
 	renameOutput, err := p.Courier.Rename(deploymentInfo.AppName, deploymentInfo.AppName+"-venerable")
+	firstDeploy := false
 	if err != nil {
 		if p.Courier.Exists(deploymentInfo.AppName) {
 			p.Log.Errorf(cannotRenameApp)
 			return nil, errors.New(string(renameOutput))
 		}
 		p.Log.Infof(newAppDetected)
+		firstDeploy = true
 	} else {
 		p.Log.Infof(renamedApp, deploymentInfo.AppName, deploymentInfo.AppName+"-venerable")
 	}
@@ -79,6 +96,29 @@ func (p Pusher) Push(appPath, domain string, deploymentInfo S.DeploymentInfo, ou
 	}
 	p.Log.Debugf(string(mapRouteOutput))
 	p.Log.Infof(appRouteCreated, deploymentInfo.AppName, domain)
+
+	healthCheck := deploymentInfo.HealthCheck
+	if healthCheck.Path == "" {
+		healthCheck = p.HealthCheck
+	}
+
+	if p.HealthChecker != nil && healthCheck.Path != "" {
+		deploymentInfo.HealthCheck = healthCheck
+		healthCheckURL := fmt.Sprintf("https://%s.%s%s", deploymentInfo.AppName, domain, healthCheck.Path)
+		p.Log.Debugf(checkingHealth, deploymentInfo.AppName, healthCheckURL)
+
+		if err := p.HealthChecker.Check(healthCheckURL, deploymentInfo); err != nil {
+			p.Log.Errorf(healthCheckFailed, deploymentInfo.AppName, err)
+			p.Rollback(deploymentInfo, firstDeploy)
+
+			logs, logsErr := p.getCloudFoundryLogs(deploymentInfo.AppName)
+			if logsErr != nil {
+				return logs, errors.New(logsErr)
+			}
+			return logs, errors.New(err)
+		}
+	}
+
 	return nil, nil
 }
 
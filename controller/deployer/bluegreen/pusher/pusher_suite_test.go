@@ -0,0 +1,13 @@
+package pusher_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestPusher(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pusher Suite")
+}
@@ -0,0 +1,105 @@
+package pusher_test
+
+import (
+	"bytes"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/compozed/deployadactyl/controller/deployer/bluegreen/pusher"
+	S "github.com/compozed/deployadactyl/structs"
+	"github.com/compozed/deployadactyl/test/mocks"
+	"github.com/op/go-logging"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Pusher", func() {
+	var (
+		courier        *mocks.Courier
+		healthChecker  *mocks.HealthChecker
+		log            *logging.Logger
+		out            *bytes.Buffer
+		deploymentInfo S.DeploymentInfo
+		healthCheck    S.HealthCheck
+		appPath        string
+		domain         string
+	)
+
+	BeforeEach(func() {
+		courier = &mocks.Courier{}
+		healthChecker = &mocks.HealthChecker{}
+		log = logging.MustGetLogger("pusher_test")
+		out = &bytes.Buffer{}
+		appPath = "/tmp/app"
+		domain = "example.com"
+		healthCheck = S.HealthCheck{Path: "/health"}
+		deploymentInfo = S.DeploymentInfo{AppName: "my-app", Instances: 1, HealthCheck: healthCheck}
+
+		courier.On("Rename", "my-app", "my-app-venerable").Return([]byte("renamed"), nil)
+		courier.On("Push", "my-app", appPath, 1).Return([]byte("pushed"), nil)
+		courier.On("MapRoute", "my-app", domain).Return([]byte("mapped"), nil)
+	})
+
+	Describe("Push", func() {
+		Context("when there is no health checker configured", func() {
+			It("does not check health and returns no error", func() {
+				p := Pusher{Courier: courier, Log: log}
+
+				logs, err := p.Push(appPath, domain, deploymentInfo, out)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(logs).To(BeNil())
+				healthChecker.AssertNotCalled(GinkgoT(), "Check", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when the health check passes", func() {
+			It("returns no error and does not roll back", func() {
+				healthChecker.On("Check", "https://my-app.example.com/health", mock.Anything).Return(nil)
+				p := Pusher{Courier: courier, Log: log, HealthChecker: healthChecker}
+
+				logs, err := p.Push(appPath, domain, deploymentInfo, out)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(logs).To(BeNil())
+				courier.AssertNotCalled(GinkgoT(), "Delete", mock.Anything)
+			})
+		})
+
+		Context("when the health check fails", func() {
+			It("rolls back the deploy and returns the health check error with the CF logs", func() {
+				healthCheckErr := errors.New("application never became healthy")
+				healthChecker.On("Check", "https://my-app.example.com/health", mock.Anything).Return(healthCheckErr)
+				courier.On("Delete", "my-app").Return([]byte("deleted"), nil)
+				courier.On("Rename", "my-app-venerable", "my-app").Return([]byte("renamed back"), nil)
+				courier.On("Logs", "my-app").Return([]byte("cf logs"), nil)
+				p := Pusher{Courier: courier, Log: log, HealthChecker: healthChecker}
+
+				logs, err := p.Push(appPath, domain, deploymentInfo, out)
+
+				Expect(err).To(MatchError(ContainSubstring(healthCheckErr.Error())))
+				Expect(logs).To(Equal([]byte("cf logs")))
+				courier.AssertCalled(GinkgoT(), "Delete", "my-app")
+				courier.AssertCalled(GinkgoT(), "Rename", "my-app-venerable", "my-app")
+			})
+
+			Context("and fetching the CF logs also fails", func() {
+				It("returns the log-retrieval error instead of the health check error", func() {
+					healthCheckErr := errors.New("application never became healthy")
+					logsErr := errors.New("could not reach loggregator")
+					healthChecker.On("Check", "https://my-app.example.com/health", mock.Anything).Return(healthCheckErr)
+					courier.On("Delete", "my-app").Return([]byte("deleted"), nil)
+					courier.On("Rename", "my-app-venerable", "my-app").Return([]byte("renamed back"), nil)
+					courier.On("Logs", "my-app").Return(nil, logsErr)
+					p := Pusher{Courier: courier, Log: log, HealthChecker: healthChecker}
+
+					_, err := p.Push(appPath, domain, deploymentInfo, out)
+
+					Expect(err).To(MatchError(ContainSubstring(logsErr.Error())))
+					Expect(err).ToNot(MatchError(ContainSubstring(healthCheckErr.Error())))
+				})
+			})
+		})
+	})
+})
@@ -0,0 +1,19 @@
+// Package interfaces provides the contracts used to abstract external
+// collaborators from the rest of deployadactyl.
+package interfaces
+
+import (
+	"io"
+
+	"github.com/spf13/afero"
+)
+
+// ArtifactFetcher downloads a remote build artifact into dest and returns
+// the number of bytes written. Implementations are selected by the scheme
+// of the URL passed to Fetch, for example "http", "https", "ftp", or
+// "sftp". If progress is non-nil, implementations write a copy of the
+// downloaded bytes to it as they arrive, mirroring what Pusher.Push
+// already writes to its out writer.
+type ArtifactFetcher interface {
+	Fetch(rawURL string, dest afero.File, progress io.Writer) (int64, error)
+}
@@ -0,0 +1,9 @@
+package interfaces
+
+import S "github.com/compozed/deployadactyl/structs"
+
+// HealthChecker verifies that a newly pushed application is healthy before
+// its blue-green predecessor is deleted.
+type HealthChecker interface {
+	Check(url string, deploymentInfo S.DeploymentInfo) error
+}
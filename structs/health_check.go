@@ -0,0 +1,35 @@
+// Package structs holds the plain data types passed between
+// deployadactyl's packages.
+package structs
+
+import "time"
+
+// HealthCheck describes how Pusher.Push verifies a newly pushed application
+// before its venerable predecessor is deleted. It can be configured per
+// environment and overridden per deploy request by setting the same field
+// names in the deploy request body.
+type HealthCheck struct {
+	// Path is appended to https://{AppName}.{Domain} to form the health
+	// check URL. An empty Path disables health checking entirely.
+	Path string `json:"path" yaml:"path"`
+
+	// ExpectedStatuses are the HTTP status codes considered healthy. A
+	// nil or empty slice defaults to any 2xx response.
+	ExpectedStatuses []int `json:"expected_statuses,omitempty" yaml:"expected_statuses,omitempty"`
+
+	// ExpectedBodyRegex, if set, must match the response body for the
+	// check to be considered healthy.
+	ExpectedBodyRegex string `json:"expected_body_regex,omitempty" yaml:"expected_body_regex,omitempty"`
+
+	// Timeout bounds a single health check request.
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+
+	// Retries is how many additional attempts are made after the first
+	// failed check, each delayed by an exponentially increasing backoff
+	// starting at BackoffInterval.
+	Retries int `json:"retries" yaml:"retries"`
+
+	// BackoffInterval is the delay before the first retry. It doubles
+	// after each subsequent retry.
+	BackoffInterval time.Duration `json:"backoff_interval" yaml:"backoff_interval"`
+}
@@ -0,0 +1,96 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// Courier is an autogenerated mock type for the Courier type
+type Courier struct {
+	mock.Mock
+}
+
+// Login provides a mock function with given fields: foundationURL, username, password, org, space, skipSSL
+func (_m *Courier) Login(foundationURL, username, password, org, space string, skipSSL bool) ([]byte, error) {
+	ret := _m.Called(foundationURL, username, password, org, space, skipSSL)
+
+	var r0 []byte
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]byte)
+	}
+
+	return r0, ret.Error(1)
+}
+
+// Delete provides a mock function with given fields: appName
+func (_m *Courier) Delete(appName string) ([]byte, error) {
+	ret := _m.Called(appName)
+
+	var r0 []byte
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]byte)
+	}
+
+	return r0, ret.Error(1)
+}
+
+// Push provides a mock function with given fields: appName, appPath, instances
+func (_m *Courier) Push(appName, appPath string, instances int) ([]byte, error) {
+	ret := _m.Called(appName, appPath, instances)
+
+	var r0 []byte
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]byte)
+	}
+
+	return r0, ret.Error(1)
+}
+
+// Rename provides a mock function with given fields: oldName, newName
+func (_m *Courier) Rename(oldName, newName string) ([]byte, error) {
+	ret := _m.Called(oldName, newName)
+
+	var r0 []byte
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]byte)
+	}
+
+	return r0, ret.Error(1)
+}
+
+// MapRoute provides a mock function with given fields: appName, domain
+func (_m *Courier) MapRoute(appName, domain string) ([]byte, error) {
+	ret := _m.Called(appName, domain)
+
+	var r0 []byte
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]byte)
+	}
+
+	return r0, ret.Error(1)
+}
+
+// Exists provides a mock function with given fields: appName
+func (_m *Courier) Exists(appName string) bool {
+	ret := _m.Called(appName)
+
+	return ret.Get(0).(bool)
+}
+
+// CleanUp provides a mock function with given fields:
+func (_m *Courier) CleanUp() error {
+	ret := _m.Called()
+
+	return ret.Error(0)
+}
+
+// Logs provides a mock function with given fields: appName
+func (_m *Courier) Logs(appName string) ([]byte, error) {
+	ret := _m.Called(appName)
+
+	var r0 []byte
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]byte)
+	}
+
+	return r0, ret.Error(1)
+}
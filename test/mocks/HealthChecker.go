@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	S "github.com/compozed/deployadactyl/structs"
+	"github.com/stretchr/testify/mock"
+)
+
+// HealthChecker is an autogenerated mock type for the HealthChecker type
+type HealthChecker struct {
+	mock.Mock
+}
+
+// Check provides a mock function with given fields: url, deploymentInfo
+func (_m *HealthChecker) Check(url string, deploymentInfo S.DeploymentInfo) error {
+	ret := _m.Called(url, deploymentInfo)
+
+	return ret.Error(0)
+}